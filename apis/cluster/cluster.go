@@ -0,0 +1,100 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cluster holds the provider-agnostic API types that describe a
+// kubicorn cluster: its ServerPools, their ProviderConfigs, and the
+// Kubernetes-facing bits (kubeconfig, API endpoint) that come out of
+// applying them.
+package cluster
+
+// Cluster is the top-level, provider-agnostic description of a kubicorn
+// cluster. Providers read it through ProviderConfig/MachineProviderConfigs
+// and write reconciled state back the same way.
+type Cluster struct {
+	Name                   string
+	KubeConfig             string
+	providerConfig         ProviderConfig
+	machineProviderConfigs []*MachineProviderConfig
+}
+
+// ProviderConfig returns the cluster-wide provider configuration.
+func (c *Cluster) ProviderConfig() ProviderConfig {
+	return c.providerConfig
+}
+
+// SetProviderConfig replaces the cluster-wide provider configuration.
+func (c *Cluster) SetProviderConfig(providerConfig ProviderConfig) {
+	c.providerConfig = providerConfig
+}
+
+// MachineProviderConfigs returns the per-ServerPool configuration.
+func (c *Cluster) MachineProviderConfigs() []*MachineProviderConfig {
+	return c.machineProviderConfigs
+}
+
+// SetMachineProviderConfigs replaces the per-ServerPool configuration.
+func (c *Cluster) SetMachineProviderConfigs(machineProviderConfigs []*MachineProviderConfig) {
+	c.machineProviderConfigs = machineProviderConfigs
+}
+
+// NewMachineSetsFromProviderConfigs appends machineProviderConfigs to the
+// cluster's per-ServerPool configuration. It does not check for an existing
+// ServerPool with the same name.
+func (c *Cluster) NewMachineSetsFromProviderConfigs(machineProviderConfigs []*MachineProviderConfig) {
+	c.machineProviderConfigs = append(c.machineProviderConfigs, machineProviderConfigs...)
+}
+
+// MachineProviderConfig pairs a ServerPool with the rest of the per-pool
+// state a provider needs to reconcile it.
+type MachineProviderConfig struct {
+	ServerPool *ServerPool
+}
+
+// ProviderConfig is the cluster-wide configuration shared by every
+// ServerPool: where it lives, how to reach it over SSH, and how the
+// Kubernetes API and its supporting components are configured.
+type ProviderConfig struct {
+	Location      string
+	SSH           SSHConfig
+	KubernetesAPI KubernetesAPI
+	Components    Components
+	Values        Values
+}
+
+// SSHConfig is the cluster-wide SSH default used to bootstrap a droplet when
+// a ServerPool doesn't set its own communicator fields.
+type SSHConfig struct {
+	Identifier           string
+	PublicKeyFingerprint string
+	PublicKeyPath        string
+	Port                 string
+	User                 string
+}
+
+// KubernetesAPI describes where the cluster's Kubernetes API can be reached.
+type KubernetesAPI struct {
+	Endpoint string
+	Port     string
+}
+
+// Components toggles optional cluster components.
+type Components struct {
+	ComponentVPN bool
+}
+
+// Values carries free-form key/value pairs injected into bootstrap script
+// templates (e.g. INJECTEDMASTER, INJECTEDCONF, INJECTEDPORT).
+type Values struct {
+	ItemMap map[string]string
+}
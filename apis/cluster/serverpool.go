@@ -0,0 +1,118 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+// ServerPoolType identifies the role a ServerPool plays in the cluster.
+type ServerPoolType string
+
+const (
+	ServerPoolTypeMaster ServerPoolType = "master"
+	ServerPoolTypeNode   ServerPoolType = "node"
+)
+
+// ServerPool describes one homogeneous group of instances a provider
+// reconciles together, e.g. "masters" or "workers".
+type ServerPool struct {
+	Name             string
+	Identifier       string
+	Type             ServerPoolType
+	MaxCount         int
+	Size             string
+	Image            string
+	BootstrapScripts []string
+
+	// ReservedIP keeps a provider-reserved IP (e.g. a DigitalOcean Floating
+	// IP) allocated across master replacement instead of releasing it on
+	// delete, so the Kubernetes API endpoint doesn't move.
+	ReservedIP bool
+
+	// CommunicatorType selects how bootstrap reaches a freshly created
+	// instance in this pool. Defaults to CommunicatorTypeSSH.
+	CommunicatorType     CommunicatorType
+	CommunicatorPort     string
+	CommunicatorUser     string
+	CommunicatorCert     string
+	CommunicatorPassword string
+
+	// RolloutStrategy controls how this pool is updated when its Expected
+	// state diverges from what's running. Nil (or Type == "") means the
+	// historical diff-and-recreate behavior.
+	RolloutStrategy *RolloutStrategy
+
+	// UserDataFormat selects how BootstrapScripts are packaged into the
+	// instance's UserData. Zero value renders plain shell, unchanged from
+	// kubicorn's historical behavior.
+	UserDataFormat UserDataFormat
+
+	// Concurrency bounds how many droplets in this pool are created at
+	// once. Zero or negative falls back to min(Count, DefaultConcurrency).
+	Concurrency int
+}
+
+// UserDataFormat selects the cloud-init/Ignition format a provider renders
+// UserData into.
+type UserDataFormat string
+
+const (
+	UserDataFormatCloudConfig UserDataFormat = "cloud-config"
+	UserDataFormatIgnition    UserDataFormat = "ignition"
+)
+
+// RolloutStrategyType selects how an out-of-date ServerPool is brought in
+// line with its Expected state.
+type RolloutStrategyType string
+
+const (
+	RolloutStrategyRecreate      RolloutStrategyType = "Recreate"
+	RolloutStrategyRollingUpdate RolloutStrategyType = "RollingUpdate"
+	RolloutStrategyCanary        RolloutStrategyType = "Canary"
+)
+
+// RolloutStrategy is modeled on apps/v1.DeploymentStrategy: a Type plus the
+// strategy-specific options for it.
+type RolloutStrategy struct {
+	Type          RolloutStrategyType
+	RollingUpdate *RollingUpdateRolloutStrategy
+	Canary        *CanaryRolloutStrategy
+}
+
+// RollingUpdateRolloutStrategy bounds how many droplets a RollingUpdate
+// rollout may surge above, or remove below, the pool's steady-state count.
+type RollingUpdateRolloutStrategy struct {
+	MaxSurge       int
+	MaxUnavailable int
+}
+
+// CanaryRolloutStrategy creates a Percent-sized batch of new droplets and
+// pauses for PauseSeconds before rolling out the rest of the pool.
+type CanaryRolloutStrategy struct {
+	Percent      int
+	PauseSeconds int
+
+	// RollingUpdate bounds the surge/unavailable rollout of the remainder of
+	// the pool once the canary batch is accepted. Nil defaults to the same
+	// MaxSurge=1/MaxUnavailable=1 a plain RollingUpdate defaults to, rather
+	// than replacing the whole remainder in one step.
+	RollingUpdate *RollingUpdateRolloutStrategy
+}
+
+// CommunicatorType identifies the remote shell protocol used to bootstrap an
+// instance in a ServerPool.
+type CommunicatorType string
+
+const (
+	CommunicatorTypeSSH   CommunicatorType = "ssh"
+	CommunicatorTypeWinRM CommunicatorType = "winrm"
+)
@@ -0,0 +1,187 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/kubicorn/kubicorn/pkg/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultConcurrency       = 8
+	DropletActiveAttempts    = 60
+	DropletActiveBackoffBase = time.Second
+	DropletActiveBackoffMax  = 30 * time.Second
+)
+
+// createDropletsConcurrently creates expected.Count droplets with at most
+// ServerPool.Concurrency in flight at once, polling each one to "active"
+// rather than trusting the create call alone. Any failure after the first
+// success rolls back every droplet created so far so nothing is left
+// orphaned and billable. Cancelling ctx stops new creates from starting and
+// unblocks anything still polling or sleeping.
+func (r *Droplet) createDropletsConcurrently(ctx context.Context, expected *Droplet, userData string, sshID int) (*godo.Droplet, error) {
+	concurrency := resolveConcurrency(r.ServerPool.Concurrency, expected.Count)
+	created, err := r.createDroplets(ctx, concurrency, expected.Count, expected, userData, sshID,
+		func(j int) string { return fmt.Sprintf("%s-%d", expected.Name, j) },
+		[]string{expected.Name},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return created[len(created)-1], nil
+}
+
+// createDroplets is the shared bounded-concurrency, rollback-on-failure core
+// behind both createDropletsConcurrently and rollout.go's surgeCreate: it
+// creates count droplets (named via nameFn, tagged with tags) with at most
+// concurrency in flight at once, polling each one to "active". Any failure
+// after the first success rolls back every droplet created so far in this
+// call so nothing is left orphaned and billable. Cancelling ctx stops new
+// creates from starting and unblocks anything still polling or sleeping.
+func (r *Droplet) createDroplets(ctx context.Context, concurrency, count int, expected *Droplet, userData string, sshID int, nameFn func(j int) string, tags []string) ([]*godo.Droplet, error) {
+	g, gctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, concurrency)
+
+	var mu sync.Mutex
+	created := make([]*godo.Droplet, 0, count)
+
+	for j := 0; j < count; j++ {
+		j := j
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+			defer func() { <-sem }()
+
+			createRequest := &godo.DropletCreateRequest{
+				Name:   nameFn(j),
+				Region: expected.Region,
+				Size:   expected.Size,
+				Image: godo.DropletCreateImage{
+					Slug: expected.Image,
+				},
+				Tags:              tags,
+				PrivateNetworking: true,
+				SSHKeys: []godo.DropletCreateSSHKey{
+					{
+						ID:          sshID,
+						Fingerprint: expected.SSHFingerprint,
+					},
+				},
+				UserData: userData,
+			}
+			droplet, _, err := Sdk.Client.Droplets.Create(gctx, createRequest)
+			if err != nil {
+				return fmt.Errorf("Unable to create droplet [%s]: %v", createRequest.Name, err)
+			}
+			logger.Success("Created Droplet [%d]", droplet.ID)
+
+			active, err := waitForDropletActive(gctx, droplet.ID)
+			if err != nil {
+				mu.Lock()
+				created = append(created, droplet)
+				mu.Unlock()
+				return err
+			}
+
+			mu.Lock()
+			created = append(created, active)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		if len(created) > 0 {
+			logger.Info("Rolling back %d partially-created droplet(s) after error: %v", len(created), err)
+			rollbackCreatedDroplets(created)
+		}
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// resolveConcurrency picks how many droplets may be created in flight at
+// once: ServerPool.Concurrency if set, otherwise min(count, DefaultConcurrency),
+// and never less than 1.
+func resolveConcurrency(configured, count int) int {
+	if configured > 0 {
+		return configured
+	}
+	if count > DefaultConcurrency {
+		return DefaultConcurrency
+	}
+	if count <= 0 {
+		return 1
+	}
+	return count
+}
+
+// waitForDropletActive polls a freshly created droplet until DigitalOcean
+// reports it active, backing off exponentially instead of assuming success
+// from the create call alone.
+func waitForDropletActive(ctx context.Context, id int) (*godo.Droplet, error) {
+	backoff := DropletActiveBackoffBase
+	for i := 0; i < DropletActiveAttempts; i++ {
+		droplet, _, err := Sdk.Client.Droplets.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if droplet.Status == "active" {
+			return droplet, nil
+		}
+		if err := sleepCtx(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+		if backoff > DropletActiveBackoffMax {
+			backoff = DropletActiveBackoffMax
+		}
+	}
+	return nil, fmt.Errorf("Droplet [%d] did not become active after %d attempts", id, DropletActiveAttempts)
+}
+
+// rollbackCreatedDroplets deletes droplets created earlier in a failed batch,
+// waiting out the same "new" status as Delete does before issuing the delete.
+// It uses context.Background() rather than the (likely already cancelled)
+// caller context, since rollback must still run to avoid orphaned droplets.
+func rollbackCreatedDroplets(created []*godo.Droplet) {
+	cleanupCtx := context.Background()
+	for _, droplet := range created {
+		for i := 0; i < DeleteAttempts; i++ {
+			current, _, err := Sdk.Client.Droplets.Get(cleanupCtx, droplet.ID)
+			if err != nil || current.Status != "new" {
+				break
+			}
+			logger.Debug("Waiting for Droplet creation to finish before rollback delete [%d]...", droplet.ID)
+			time.Sleep(DeleteSleepSecondsPerAttempt * time.Second)
+		}
+		if _, err := Sdk.Client.Droplets.Delete(cleanupCtx, droplet.ID); err != nil {
+			logger.Info("Unable to roll back Droplet [%d]: %v", droplet.ID, err)
+			continue
+		}
+		logger.Success("Rolled back Droplet [%d]", droplet.ID)
+	}
+}
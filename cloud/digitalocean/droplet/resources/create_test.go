@@ -0,0 +1,38 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import "testing"
+
+func TestResolveConcurrency(t *testing.T) {
+	cases := []struct {
+		name              string
+		configured, count int
+		want              int
+	}{
+		{name: "explicit value wins", configured: 3, count: 100, want: 3},
+		{name: "unset falls back to count", configured: 0, count: 4, want: 4},
+		{name: "unset clamps to DefaultConcurrency", configured: 0, count: 100, want: DefaultConcurrency},
+		{name: "negative treated as unset", configured: -1, count: 2, want: 2},
+		{name: "zero count never returns zero", configured: 0, count: 0, want: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := resolveConcurrency(c.configured, c.count); got != c.want {
+				t.Errorf("resolveConcurrency(%d, %d) = %d, want %d", c.configured, c.count, got, c.want)
+			}
+		})
+	}
+}
@@ -24,11 +24,10 @@ import (
 	"github.com/digitalocean/godo"
 	"github.com/kubicorn/kubicorn/apis/cluster"
 	"github.com/kubicorn/kubicorn/cloud"
+	"github.com/kubicorn/kubicorn/pkg/comms"
 	"github.com/kubicorn/kubicorn/pkg/compare"
 	"github.com/kubicorn/kubicorn/pkg/logger"
-	"github.com/kubicorn/kubicorn/pkg/scp"
 	"github.com/kubicorn/kubicorn/pkg/script"
-	"github.com/kubicorn/kubicorn/pkg/ssh"
 )
 
 var _ cloud.Resource = &Droplet{}
@@ -51,7 +50,7 @@ const (
 	DeleteSleepSecondsPerAttempt   = 3
 )
 
-func (r *Droplet) Actual(immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+func (r *Droplet) Actual(ctx context.Context, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
 	logger.Debug("droplet.Actual")
 	newResource := &Droplet{
 		Shared: Shared{
@@ -60,7 +59,7 @@ func (r *Droplet) Actual(immutable *cluster.Cluster) (*cluster.Cluster, cloud.Re
 		},
 	}
 
-	droplets, _, err := Sdk.Client.Droplets.ListByTag(context.TODO(), r.Name, &godo.ListOptions{})
+	droplets, _, err := Sdk.Client.Droplets.ListByTag(ctx, r.Name, &godo.ListOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -88,7 +87,7 @@ func (r *Droplet) Actual(immutable *cluster.Cluster) (*cluster.Cluster, cloud.Re
 	return newCluster, newResource, nil
 }
 
-func (r *Droplet) Expected(immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+func (r *Droplet) Expected(ctx context.Context, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
 	logger.Debug("droplet.Expected")
 	newResource := &Droplet{
 		Shared: Shared{
@@ -107,7 +106,7 @@ func (r *Droplet) Expected(immutable *cluster.Cluster) (*cluster.Cluster, cloud.
 	return newCluster, newResource, nil
 }
 
-func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+func (r *Droplet) Apply(ctx context.Context, actual, expected cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
 	logger.Debug("droplet.Apply")
 	applyResource := expected.(*Droplet)
 	isEqual, err := compare.IsEqual(actual.(*Droplet), expected.(*Droplet))
@@ -122,6 +121,7 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 
 	masterIpPrivate := ""
 	masterIPPublic := ""
+	rawVPNConfig := ""
 	providerConfig := immutable.ProviderConfig()
 	if r.ServerPool.Type == cluster.ServerPoolTypeNode {
 		found := false
@@ -137,16 +137,20 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 			if masterTag == "" {
 				return nil, nil, fmt.Errorf("Unable to find master tag for master IP")
 			}
-			droplets, _, err := Sdk.Client.Droplets.ListByTag(context.TODO(), masterTag, &godo.ListOptions{})
+			droplets, _, err := Sdk.Client.Droplets.ListByTag(ctx, masterTag, &godo.ListOptions{})
 			if err != nil {
 				logger.Debug("Hanging for master IP.. (%v)", err)
-				time.Sleep(time.Duration(MasterIPSleepSecondsPerAttempt) * time.Second)
+				if err := sleepCtx(ctx, time.Duration(MasterIPSleepSecondsPerAttempt)*time.Second); err != nil {
+					return nil, nil, err
+				}
 				continue
 			}
 			ld := len(droplets)
 			if ld == 0 {
 				logger.Debug("Hanging for master IP..")
-				time.Sleep(time.Duration(MasterIPSleepSecondsPerAttempt) * time.Second)
+				if err := sleepCtx(ctx, time.Duration(MasterIPSleepSecondsPerAttempt)*time.Second); err != nil {
+					return nil, nil, err
+				}
 				continue
 			}
 			if ld > 1 {
@@ -157,7 +161,9 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 			masterIPPublic, err = droplet.PublicIPv4()
 			if err != nil || masterIPPublic == "" {
 				logger.Debug("Hanging for master IP..")
-				time.Sleep(time.Duration(MasterIPSleepSecondsPerAttempt) * time.Second)
+				if err := sleepCtx(ctx, time.Duration(MasterIPSleepSecondsPerAttempt)*time.Second); err != nil {
+					return nil, nil, err
+				}
 				continue
 			}
 
@@ -170,37 +176,46 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 				found = true
 			} else {
 				logger.Info("Setting up VPN on Droplets... this could take a little bit longer...")
-				//pubPath := local.Expand(immutable.ProviderConfig().SSH.PublicKeyPath)
-				//privPath := strings.Replace(pubPath, ".pub", "", 1)
 
-				client := ssh.NewSSHClient(masterIPPublic, providerConfig.SSH.Port,
-											providerConfig.SSH.User, providerConfig.SSH.PublicKeyPath)
-				err = client.Connect()
+				communicator, err := comms.NewCommunicator(r.ServerPool, providerConfig, masterIPPublic)
+				if err != nil {
+					return nil, nil, err
+				}
+				err = communicator.Connect()
 				if err != nil {
-					return nil, nil, fmt.Errorf("Unable to connect to SSH: %v", err)
+					return nil, nil, fmt.Errorf("Unable to connect to [%s]: %v", r.ServerPool.CommunicatorType, err)
 				}
 
-				masterVpnIP, err := scp.ReadBytes(client, "/tmp/.ip")
+				masterVpnIP, err := communicator.ReadFile("/tmp/.ip")
 				if err != nil {
 					logger.Debug("Hanging for VPN IP.. /tmp/.ip (%v)", err)
-					time.Sleep(time.Duration(MasterIPSleepSecondsPerAttempt) * time.Second)
+					if err := sleepCtx(ctx, time.Duration(MasterIPSleepSecondsPerAttempt)*time.Second); err != nil {
+						return nil, nil, err
+					}
 					continue
 				}
 				masterIpPrivate = strings.Replace(string(masterVpnIP), "\n", "", -1)
-				openvpnConfig, err := scp.ReadBytes(client, "/tmp/clients.conf")
+				openvpnConfig, err := communicator.ReadFile("/tmp/clients.conf")
 				if err != nil {
 					logger.Debug("Hanging for VPN config.. /tmp/clients.ovpn (%v)", err)
-					time.Sleep(time.Duration(MasterIPSleepSecondsPerAttempt) * time.Second)
+					if err := sleepCtx(ctx, time.Duration(MasterIPSleepSecondsPerAttempt)*time.Second); err != nil {
+						return nil, nil, err
+					}
 					continue
 				}
 
-				openvpnConfigEscaped := strings.Replace(string(openvpnConfig), "\n", "\\n", -1)
-				providerConfig.Values.ItemMap["INJECTEDCONF"] = openvpnConfigEscaped
+				rawVPNConfig = string(openvpnConfig)
+				// ShellScriptRenderer templates bootstrap scripts directly against
+				// ItemMap, where INJECTEDCONF is historically assigned into a single
+				// shell variable, so it still needs the legacy single-line escaping.
+				// The cloud-config/Ignition renderers get the real multi-line content
+				// via RenderValues.VPNConfig below, since they write it as its own file.
+				providerConfig.Values.ItemMap["INJECTEDCONF"] = strings.Replace(rawVPNConfig, "\n", "\\n", -1)
 				found = true
 
-				err = client.Close()
+				err = communicator.Close()
 				if err != nil {
-					return nil, nil, fmt.Errorf("Error closing SSH connection: %v", err)
+					return nil, nil, fmt.Errorf("Error closing [%s] connection: %v", r.ServerPool.CommunicatorType, err)
 				}
 			}
 
@@ -215,7 +230,13 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 	providerConfig.Values.ItemMap["INJECTEDPORT"] = immutable.ProviderConfig().KubernetesAPI.Port
 	immutable.SetProviderConfig(providerConfig)
 
-	userData, err := script.BuildBootstrapScript(r.ServerPool.BootstrapScripts, immutable)
+	renderer := script.NewRenderer(r.ServerPool.UserDataFormat)
+	userData, err := renderer.Render(r.ServerPool.BootstrapScripts, immutable, script.RenderValues{
+		MasterEndpoint: masterIpPrivate,
+		Port:           immutable.ProviderConfig().KubernetesAPI.Port,
+		VPNConfig:      rawVPNConfig,
+		ItemMap:        providerConfig.Values.ItemMap,
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -226,29 +247,30 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 	}
 
 	var droplet *godo.Droplet
-	for j := 0; j < expected.(*Droplet).Count; j++ {
-		createRequest := &godo.DropletCreateRequest{
-			Name:   fmt.Sprintf("%s-%d", expected.(*Droplet).Name, j),
-			Region: expected.(*Droplet).Region,
-			Size:   expected.(*Droplet).Size,
-			Image: godo.DropletCreateImage{
-				Slug: expected.(*Droplet).Image,
-			},
-			Tags:              []string{expected.(*Droplet).Name},
-			PrivateNetworking: true,
-			SSHKeys: []godo.DropletCreateSSHKey{
-				{
-					ID:          sshID,
-					Fingerprint: expected.(*Droplet).SSHFingerprint,
-				},
-			},
-			UserData: string(userData),
+	actualDroplet := actual.(*Droplet)
+	rollout := r.ServerPool.RolloutStrategy
+	existingDroplets, _, err := Sdk.Client.Droplets.ListByTag(ctx, r.Name, &godo.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(existingDroplets) > 0 && actualDroplet.Image != "" && rollout != nil && rollout.Type != "" && rollout.Type != cluster.RolloutStrategyRecreate {
+		switch rollout.Type {
+		case cluster.RolloutStrategyRollingUpdate:
+			droplet, err = r.rollingUpdateApply(ctx, existingDroplets, expected.(*Droplet), immutable, string(userData), sshID, rollout.RollingUpdate)
+		case cluster.RolloutStrategyCanary:
+			droplet, err = r.canaryApply(ctx, existingDroplets, expected.(*Droplet), immutable, string(userData), sshID, rollout.Canary)
+		default:
+			err = fmt.Errorf("Unknown rollout strategy [%s]", rollout.Type)
 		}
-		droplet, _, err = Sdk.Client.Droplets.Create(context.TODO(), createRequest)
 		if err != nil {
 			return nil, nil, err
 		}
-		logger.Success("Created Droplet [%d]", droplet.ID)
+	} else {
+		droplet, err = r.createDropletsConcurrently(ctx, expected.(*Droplet), string(userData), sshID)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	newResource := &Droplet{
@@ -263,29 +285,60 @@ func (r *Droplet) Apply(actual, expected cloud.Resource, immutable *cluster.Clus
 		BootstrapScripts: expected.(*Droplet).BootstrapScripts,
 	}
 
-	providerConfig = immutable.ProviderConfig()
-	providerConfig.KubernetesAPI.Endpoint = masterIPPublic
-	immutable.SetProviderConfig(providerConfig)
+	// Only the master pool's Apply owns KubernetesAPI.Endpoint. Node pools run
+	// in the same `kubicorn apply` after the master, and masterIPPublic here is
+	// the master's ephemeral public IP - the very thing the Floating IP exists
+	// to stop using as the persisted endpoint - so leave it untouched for them.
+	if r.ServerPool.Type == cluster.ServerPoolTypeMaster {
+		floatingIP := &FloatingIP{
+			Shared:     Shared{Name: r.ServerPool.Name},
+			Region:     immutable.ProviderConfig().Location,
+			DropletID:  droplet.ID,
+			ServerPool: r.ServerPool,
+		}
+		_, actualFloatingIP, err := floatingIP.Actual(ctx, immutable)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, expectedFloatingIP, err := floatingIP.Expected(ctx, immutable)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, appliedFloatingIP, err := floatingIP.Apply(ctx, actualFloatingIP, expectedFloatingIP, immutable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to reserve Kubernetes API floating IP: %v", err)
+		}
+		reservedIP := appliedFloatingIP.(*FloatingIP)
+		if err := reservedIP.assignAndWait(ctx, reservedIP.CloudID, droplet.ID); err != nil {
+			return nil, nil, err
+		}
+
+		providerConfig = immutable.ProviderConfig()
+		providerConfig.KubernetesAPI.Endpoint = reservedIP.CloudID
+		immutable.SetProviderConfig(providerConfig)
+	}
 
 	newCluster := r.immutableRender(newResource, immutable)
 	return newCluster, newResource, nil
 }
-func (r *Droplet) Delete(actual cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+func (r *Droplet) Delete(ctx context.Context, actual cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
 	logger.Debug("droplet.Delete")
 	deleteResource := actual.(*Droplet)
 	if deleteResource.Name == "" {
 		return nil, nil, fmt.Errorf("Unable to delete droplet resource without Name [%s]", deleteResource.Name)
 	}
 
-	droplets, _, err := Sdk.Client.Droplets.ListByTag(context.TODO(), r.Name, &godo.ListOptions{})
+	droplets, _, err := Sdk.Client.Droplets.ListByTag(ctx, r.Name, &godo.ListOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
 	if len(droplets) != actual.(*Droplet).Count {
 		for i := 0; i < DeleteAttempts; i++ {
 			logger.Info("Droplet count mis-match, trying query again")
-			time.Sleep(5 * time.Second)
-			droplets, _, err = Sdk.Client.Droplets.ListByTag(context.TODO(), r.Name, &godo.ListOptions{})
+			if err := sleepCtx(ctx, 5*time.Second); err != nil {
+				return nil, nil, err
+			}
+			droplets, _, err = Sdk.Client.Droplets.ListByTag(ctx, r.Name, &godo.ListOptions{})
 			if err != nil {
 				return nil, nil, err
 			}
@@ -299,18 +352,34 @@ func (r *Droplet) Delete(actual cloud.Resource, immutable *cluster.Cluster) (*cl
 		for i := 0; i < DeleteAttempts; i++ {
 			if droplet.Status == "new" {
 				logger.Debug("Waiting for Droplet creation to finish [%d]...", droplet.ID)
-				time.Sleep(DeleteSleepSecondsPerAttempt * time.Second)
+				if err := sleepCtx(ctx, DeleteSleepSecondsPerAttempt*time.Second); err != nil {
+					return nil, nil, err
+				}
 			} else {
 				break
 			}
 		}
-		_, err = Sdk.Client.Droplets.Delete(context.TODO(), droplet.ID)
+		_, err = Sdk.Client.Droplets.Delete(ctx, droplet.ID)
 		if err != nil {
 			return nil, nil, err
 		}
 		logger.Success("Deleted Droplet [%d]", droplet.ID)
 	}
 
+	if r.ServerPool.Type == cluster.ServerPoolTypeMaster {
+		floatingIP := &FloatingIP{
+			Shared:     Shared{Name: r.Name},
+			ServerPool: r.ServerPool,
+		}
+		_, actualFloatingIP, err := floatingIP.Actual(ctx, immutable)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, _, err := floatingIP.Delete(ctx, actualFloatingIP, immutable); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Kubernetes API
 	// todo (@kris-nova) this is obviously not immutable
 	immutable.ProviderConfig().KubernetesAPI.Endpoint = ""
@@ -0,0 +1,203 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/kubicorn/kubicorn/cloud"
+	"github.com/kubicorn/kubicorn/pkg/compare"
+	"github.com/kubicorn/kubicorn/pkg/logger"
+)
+
+var _ cloud.Resource = &FloatingIP{}
+
+const (
+	FloatingIPBindAttempts               = 25
+	FloatingIPBindSleepSecondsPerAttempt = 5
+)
+
+// FloatingIP reserves a DigitalOcean floating IP and keeps it assigned to the
+// master droplet for the pool, so the Kubernetes API endpoint survives master
+// replacement instead of tracking whichever droplet happens to be "first".
+type FloatingIP struct {
+	Shared
+	Region     string
+	DropletID  int
+	ServerPool *cluster.ServerPool
+}
+
+func (r *FloatingIP) Actual(ctx context.Context, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+	logger.Debug("floatingIP.Actual")
+	newResource := &FloatingIP{
+		Shared: Shared{
+			Name: r.Name,
+		},
+	}
+
+	ip := immutable.ProviderConfig().Values.ItemMap["INJECTEDFLOATINGIP"]
+	if ip != "" {
+		found, _, err := Sdk.Client.FloatingIPs.Get(ctx, ip)
+		if err != nil {
+			return nil, nil, err
+		}
+		newResource.CloudID = found.IP
+		newResource.Region = found.Region.Slug
+		if found.Droplet != nil {
+			newResource.DropletID = found.Droplet.ID
+		}
+	}
+
+	newResource.Region = immutable.ProviderConfig().Location
+	newCluster := r.immutableRender(newResource, immutable)
+	return newCluster, newResource, nil
+}
+
+func (r *FloatingIP) Expected(ctx context.Context, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+	logger.Debug("floatingIP.Expected")
+	newResource := &FloatingIP{
+		Shared: Shared{
+			Name: r.Name,
+		},
+		Region:    immutable.ProviderConfig().Location,
+		DropletID: r.DropletID,
+	}
+
+	newCluster := r.immutableRender(newResource, immutable)
+	return newCluster, newResource, nil
+}
+
+func (r *FloatingIP) Apply(ctx context.Context, actual, expected cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+	logger.Debug("floatingIP.Apply")
+	applyResource := expected.(*FloatingIP)
+	isEqual, err := compare.IsEqual(actual.(*FloatingIP), expected.(*FloatingIP))
+	if err != nil {
+		return nil, nil, err
+	}
+	if isEqual {
+		return immutable, applyResource, nil
+	}
+
+	actualIP := actual.(*FloatingIP)
+	ip := actualIP.CloudID
+	if ip == "" {
+		created, _, err := Sdk.Client.FloatingIPs.Create(ctx, &godo.FloatingIPCreateRequest{
+			Region: applyResource.Region,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to reserve floating IP: %v", err)
+		}
+		ip = created.IP
+		logger.Success("Reserved Floating IP [%s]", ip)
+	}
+
+	newResource := &FloatingIP{
+		Shared: Shared{
+			Name:    r.Name,
+			CloudID: ip,
+		},
+		Region:    applyResource.Region,
+		DropletID: r.DropletID,
+	}
+
+	providerConfig := immutable.ProviderConfig()
+	providerConfig.Values.ItemMap["INJECTEDFLOATINGIP"] = ip
+	immutable.SetProviderConfig(providerConfig)
+
+	newCluster := r.immutableRender(newResource, immutable)
+	return newCluster, newResource, nil
+}
+
+func (r *FloatingIP) Delete(ctx context.Context, actual cloud.Resource, immutable *cluster.Cluster) (*cluster.Cluster, cloud.Resource, error) {
+	logger.Debug("floatingIP.Delete")
+	deleteResource := actual.(*FloatingIP)
+	if deleteResource.CloudID == "" {
+		return immutable, &FloatingIP{}, nil
+	}
+
+	if deleteResource.DropletID != 0 {
+		_, _, err := Sdk.Client.FloatingIPActions.Unassign(ctx, deleteResource.CloudID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to unassign floating IP [%s]: %v", deleteResource.CloudID, err)
+		}
+	}
+
+	if r.ServerPool != nil && r.ServerPool.ReservedIP {
+		logger.Info("Retaining reserved Floating IP [%s] (ServerPool.ReservedIP=true)", deleteResource.CloudID)
+	} else {
+		_, err := Sdk.Client.FloatingIPs.Delete(ctx, deleteResource.CloudID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to release floating IP [%s]: %v", deleteResource.CloudID, err)
+		}
+		logger.Success("Released Floating IP [%s]", deleteResource.CloudID)
+	}
+
+	providerConfig := immutable.ProviderConfig()
+	delete(providerConfig.Values.ItemMap, "INJECTEDFLOATINGIP")
+	providerConfig.KubernetesAPI.Endpoint = ""
+	immutable.SetProviderConfig(providerConfig)
+
+	newCluster := r.immutableRender(&FloatingIP{}, immutable)
+	return newCluster, &FloatingIP{}, nil
+}
+
+// assignAndWait assigns the floating IP to dropletID and blocks until DigitalOcean
+// reports it bound, so callers never write down an endpoint that isn't live yet.
+func (r *FloatingIP) assignAndWait(ctx context.Context, ip string, dropletID int) error {
+	_, _, err := Sdk.Client.FloatingIPActions.Assign(ctx, ip, dropletID)
+	if err != nil {
+		return fmt.Errorf("unable to assign floating IP [%s] to droplet [%d]: %v", ip, dropletID, err)
+	}
+
+	for i := 0; i < FloatingIPBindAttempts; i++ {
+		found, _, err := Sdk.Client.FloatingIPs.Get(ctx, ip)
+		if err != nil {
+			return err
+		}
+		if found.Droplet != nil && found.Droplet.ID == dropletID {
+			droplet, _, err := Sdk.Client.Droplets.Get(ctx, dropletID)
+			if err != nil {
+				return err
+			}
+			for _, network := range droplet.Networks.V4 {
+				if network.IPAddress == ip {
+					return nil
+				}
+			}
+		}
+		logger.Debug("Waiting for Floating IP [%s] to bind to droplet [%d]...", ip, dropletID)
+		if err := sleepCtx(ctx, time.Duration(FloatingIPBindSleepSecondsPerAttempt)*time.Second); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("floating IP [%s] did not bind to droplet [%d] after %d attempts", ip, dropletID, FloatingIPBindAttempts)
+}
+
+func (r *FloatingIP) immutableRender(newResource cloud.Resource, inaccurateCluster *cluster.Cluster) *cluster.Cluster {
+	logger.Debug("floatingIP.Render")
+	newCluster := inaccurateCluster
+	newFloatingIP := newResource.(*FloatingIP)
+	if newFloatingIP.CloudID != "" {
+		providerConfig := newCluster.ProviderConfig()
+		providerConfig.KubernetesAPI.Endpoint = newFloatingIP.CloudID
+		newCluster.SetProviderConfig(providerConfig)
+	}
+	return newCluster
+}
@@ -0,0 +1,343 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/kubicorn/kubicorn/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	generationTagPrefix = "kubicorn-generation-"
+	nodeReadyAttempts   = 60
+	nodeReadySleep      = 5 * time.Second
+)
+
+func generationTag(generation int) string {
+	return fmt.Sprintf("%s%d", generationTagPrefix, generation)
+}
+
+// currentGeneration returns the highest kubicorn-generation tag already
+// present on droplets, or 0 when the pool predates generation tagging.
+func currentGeneration(droplets []godo.Droplet) int {
+	generation := 0
+	for _, droplet := range droplets {
+		for _, tag := range droplet.Tags {
+			if !strings.HasPrefix(tag, generationTagPrefix) {
+				continue
+			}
+			if g, err := strconv.Atoi(strings.TrimPrefix(tag, generationTagPrefix)); err == nil && g > generation {
+				generation = g
+			}
+		}
+	}
+	return generation
+}
+
+// surgeCreate creates count new droplets tagged with the given generation,
+// used for both surge and canary creation during a rollout. startIndex lets
+// callers that issue several surgeCreate calls for the same generation (a
+// multi-round RollingUpdate, or a Canary batch followed by its own
+// RollingUpdate) keep droplet names unique instead of every round
+// restarting at -0. Creation goes through the same bounded-concurrency,
+// rollback-on-partial-failure path as a full pool create (createDroplets),
+// so a surge batch that fails partway through doesn't orphan the droplets
+// it already created.
+func (r *Droplet) surgeCreate(ctx context.Context, expected *Droplet, userData string, sshID, count, generation, startIndex int) ([]*godo.Droplet, error) {
+	concurrency := resolveConcurrency(r.ServerPool.Concurrency, count)
+	created, err := r.createDroplets(ctx, concurrency, count, expected, userData, sshID,
+		func(j int) string { return fmt.Sprintf("%s-%d-%d", expected.Name, generation, startIndex+j) },
+		[]string{expected.Name, generationTag(generation)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create surge droplet(s): %v", err)
+	}
+	return created, nil
+}
+
+// waitForNodesReady blocks until every created droplet has registered as a
+// Ready node against the cluster's Kubernetes API, so cutover never happens
+// before the surge capacity can actually take traffic.
+func (r *Droplet) waitForNodesReady(ctx context.Context, immutable *cluster.Cluster, droplets []*godo.Droplet) error {
+	client, err := kubernetesClientFor(immutable)
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[string]bool, len(droplets))
+	for _, droplet := range droplets {
+		pending[droplet.Name] = true
+	}
+
+	for i := 0; i < nodeReadyAttempts && len(pending) > 0; i++ {
+		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logger.Debug("Waiting for node list... (%v)", err)
+			if err := sleepCtx(ctx, nodeReadySleep); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, node := range nodes.Items {
+			if !pending[node.Name] {
+				continue
+			}
+			if nodeIsReady(&node) {
+				delete(pending, node.Name)
+			}
+		}
+		if len(pending) > 0 {
+			logger.Debug("Waiting for [%d] surge node(s) to become Ready...", len(pending))
+			if err := sleepCtx(ctx, nodeReadySleep); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("Surge droplets did not become Ready in time: %d remaining", len(pending))
+	}
+	return nil
+}
+
+func nodeIsReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// cordonAndDrainDroplets marks each droplet's Kubernetes node unschedulable
+// and evicts its pods before the droplet is deleted.
+func (r *Droplet) cordonAndDrainDroplets(ctx context.Context, immutable *cluster.Cluster, droplets []godo.Droplet) error {
+	client, err := kubernetesClientFor(immutable)
+	if err != nil {
+		return err
+	}
+
+	for _, droplet := range droplets {
+		logger.Info("Cordoning node [%s]...", droplet.Name)
+		patch := []byte(`{"spec":{"unschedulable":true}}`)
+		if _, err := client.CoreV1().Nodes().Patch(ctx, droplet.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("Unable to cordon node [%s]: %v", droplet.Name, err)
+		}
+
+		pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + droplet.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("Unable to list pods on node [%s]: %v", droplet.Name, err)
+		}
+
+		logger.Info("Draining node [%s] (%d pods)...", droplet.Name, len(pods.Items))
+		for _, pod := range pods.Items {
+			eviction := &policyv1beta1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			}
+			if err := client.PolicyV1beta1().Evictions(pod.Namespace).Evict(ctx, eviction); err != nil {
+				return fmt.Errorf("Unable to evict pod [%s/%s]: %v", pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Droplet) deleteDroplets(ctx context.Context, droplets []godo.Droplet) error {
+	for _, droplet := range droplets {
+		if _, err := Sdk.Client.Droplets.Delete(ctx, droplet.ID); err != nil {
+			return fmt.Errorf("Unable to delete droplet [%d]: %v", droplet.ID, err)
+		}
+		logger.Success("Deleted Droplet [%d]", droplet.ID)
+	}
+	return nil
+}
+
+// rollingUpdateApply replaces oldDroplets with freshly created ones, never
+// surging past MaxSurge or removing more than MaxUnavailable at a time.
+func (r *Droplet) rollingUpdateApply(ctx context.Context, oldDroplets []godo.Droplet, expected *Droplet, immutable *cluster.Cluster, userData string, sshID int, strategy *cluster.RollingUpdateRolloutStrategy) (*godo.Droplet, error) {
+	generation := currentGeneration(oldDroplets) + 1
+	return r.rollingUpdateFromGeneration(ctx, oldDroplets, expected, immutable, userData, sshID, strategy, generation, 0)
+}
+
+// rollingUpdateFromGeneration is the shared core of rollingUpdateApply and
+// the post-canary rollout: it takes the generation and starting name index
+// as parameters so a caller that already created droplets for this
+// generation (canaryApply) doesn't collide with them.
+func (r *Droplet) rollingUpdateFromGeneration(ctx context.Context, oldDroplets []godo.Droplet, expected *Droplet, immutable *cluster.Cluster, userData string, sshID int, strategy *cluster.RollingUpdateRolloutStrategy, generation, startIndex int) (*godo.Droplet, error) {
+	maxSurge := 1
+	maxUnavailable := 1
+	if strategy != nil {
+		if strategy.MaxSurge > 0 {
+			maxSurge = strategy.MaxSurge
+		}
+		if strategy.MaxUnavailable > 0 {
+			maxUnavailable = strategy.MaxUnavailable
+		}
+	}
+
+	remaining := oldDroplets
+	nextIndex := startIndex
+	var lastCreated *godo.Droplet
+
+	for len(remaining) > 0 {
+		surgeCount := maxSurge
+		if surgeCount > len(remaining) {
+			surgeCount = len(remaining)
+		}
+		created, err := r.surgeCreate(ctx, expected, userData, sshID, surgeCount, generation, nextIndex)
+		if err != nil {
+			return nil, err
+		}
+		nextIndex += surgeCount
+		lastCreated = created[len(created)-1]
+
+		if err := r.waitForNodesReady(ctx, immutable, created); err != nil {
+			return nil, err
+		}
+
+		retiring := remaining[:surgeCount]
+		for len(retiring) > 0 {
+			unavailable := maxUnavailable
+			if unavailable > len(retiring) {
+				unavailable = len(retiring)
+			}
+			toRemove := retiring[:unavailable]
+			if err := r.cordonAndDrainDroplets(ctx, immutable, toRemove); err != nil {
+				return nil, err
+			}
+			if err := r.deleteDroplets(ctx, toRemove); err != nil {
+				return nil, err
+			}
+			retiring = retiring[unavailable:]
+		}
+		remaining = remaining[surgeCount:]
+	}
+
+	return lastCreated, nil
+}
+
+// canaryCount returns how many of total droplets a Percent-based canary
+// batch should cover: at least 1 (if total > 0), never more than total.
+func canaryCount(total, percent int) int {
+	if total <= 0 {
+		return 0
+	}
+	count := (total*percent + 99) / 100
+	if count < 1 {
+		count = 1
+	}
+	if count > total {
+		count = total
+	}
+	return count
+}
+
+// splitCanaryReplacement divides oldDroplets into the ones the canary batch
+// already replaces (toRemove, the first count) and the ones still needing a
+// RollingUpdate pass (toReplace, the rest). Canary droplets are always
+// freshly named, so they can never appear in oldDroplets themselves -
+// matching names is not how the split is decided.
+func splitCanaryReplacement(oldDroplets []godo.Droplet, count int) (toRemove, toReplace []godo.Droplet) {
+	if count > len(oldDroplets) {
+		count = len(oldDroplets)
+	}
+	return oldDroplets[:count], oldDroplets[count:]
+}
+
+// canaryApply creates a small Percent-sized batch of new droplets, pauses for
+// PauseSeconds to let the operator observe them, retires that many old
+// droplets (the canaries are their replacements, not additional capacity),
+// then rolls the remaining old droplets over with the same surge/drain
+// mechanics as a RollingUpdate.
+func (r *Droplet) canaryApply(ctx context.Context, oldDroplets []godo.Droplet, expected *Droplet, immutable *cluster.Cluster, userData string, sshID int, strategy *cluster.CanaryRolloutStrategy) (*godo.Droplet, error) {
+	percent := 10
+	pauseSeconds := 60
+	if strategy != nil {
+		if strategy.Percent > 0 {
+			percent = strategy.Percent
+		}
+		if strategy.PauseSeconds > 0 {
+			pauseSeconds = strategy.PauseSeconds
+		}
+	}
+
+	generation := currentGeneration(oldDroplets) + 1
+	count := canaryCount(len(oldDroplets), percent)
+
+	canaries, err := r.surgeCreate(ctx, expected, userData, sshID, count, generation, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.waitForNodesReady(ctx, immutable, canaries); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Pausing %d seconds to observe canary Droplet(s)...", pauseSeconds)
+	if err := sleepCtx(ctx, time.Duration(pauseSeconds)*time.Second); err != nil {
+		return nil, err
+	}
+
+	toRemove, toReplace := splitCanaryReplacement(oldDroplets, count)
+	if err := r.cordonAndDrainDroplets(ctx, immutable, toRemove); err != nil {
+		return nil, err
+	}
+	if err := r.deleteDroplets(ctx, toRemove); err != nil {
+		return nil, err
+	}
+
+	if len(toReplace) == 0 {
+		return canaries[len(canaries)-1], nil
+	}
+
+	var rollingUpdate *cluster.RollingUpdateRolloutStrategy
+	if strategy != nil {
+		rollingUpdate = strategy.RollingUpdate
+	}
+	rest, err := r.rollingUpdateFromGeneration(ctx, toReplace, expected, immutable, userData, sshID, rollingUpdate, generation, count)
+	if err != nil {
+		return nil, err
+	}
+	return rest, nil
+}
+
+func kubernetesClientFor(immutable *cluster.Cluster) (kubernetes.Interface, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(immutable.KubeConfig))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to build Kubernetes client from KubeConfig: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to build Kubernetes client from KubeConfig: %v", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/digitalocean/godo"
+)
+
+func TestCanaryCount(t *testing.T) {
+	cases := []struct {
+		total, percent, want int
+	}{
+		{total: 0, percent: 10, want: 0},
+		{total: 10, percent: 10, want: 1},
+		{total: 10, percent: 50, want: 5},
+		{total: 3, percent: 10, want: 1},
+		{total: 3, percent: 100, want: 3},
+		{total: 3, percent: 200, want: 3},
+	}
+	for _, c := range cases {
+		if got := canaryCount(c.total, c.percent); got != c.want {
+			t.Errorf("canaryCount(%d, %d) = %d, want %d", c.total, c.percent, got, c.want)
+		}
+	}
+}
+
+func TestSplitCanaryReplacementConverges(t *testing.T) {
+	old := []godo.Droplet{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+	count := canaryCount(len(old), 40) // -> 2
+
+	toRemove, toReplace := splitCanaryReplacement(old, count)
+	if len(toRemove) != count {
+		t.Fatalf("len(toRemove) = %d, want %d", len(toRemove), count)
+	}
+	if len(toReplace) != len(old)-count {
+		t.Fatalf("len(toReplace) = %d, want %d", len(toReplace), len(old)-count)
+	}
+	// The pool after canaries + a RollingUpdate over toReplace must stay at
+	// len(old): count canaries replace toRemove 1:1, and toReplace is
+	// replaced 1:1 by the follow-up RollingUpdate.
+	finalCount := count + len(toReplace)
+	if finalCount != len(old) {
+		t.Fatalf("canary rollout converges to %d droplets, want %d", finalCount, len(old))
+	}
+}
+
+func TestSplitCanaryReplacementClampsToTotal(t *testing.T) {
+	old := []godo.Droplet{{ID: 1}, {ID: 2}}
+	toRemove, toReplace := splitCanaryReplacement(old, 10)
+	if len(toRemove) != len(old) {
+		t.Fatalf("len(toRemove) = %d, want %d", len(toRemove), len(old))
+	}
+	if len(toReplace) != 0 {
+		t.Fatalf("len(toReplace) = %d, want 0", len(toReplace))
+	}
+}
+
+func TestCurrentGeneration(t *testing.T) {
+	droplets := []godo.Droplet{
+		{Tags: []string{"pool", generationTag(1)}},
+		{Tags: []string{"pool", generationTag(3)}},
+		{Tags: []string{"pool", generationTag(2)}},
+	}
+	if g := currentGeneration(droplets); g != 3 {
+		t.Errorf("currentGeneration() = %d, want 3", g)
+	}
+	if g := currentGeneration(nil); g != 0 {
+		t.Errorf("currentGeneration(nil) = %d, want 0", g)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloud defines the reconciliation contract every provider resource
+// implements.
+package cloud
+
+import (
+	"context"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+)
+
+// Resource is a single piece of provider state (a droplet, a floating IP,
+// ...) that can be diffed and reconciled against a Cluster. Every method
+// takes ctx so a cancelled apply/delete unwinds promptly instead of running
+// to completion.
+type Resource interface {
+	Actual(ctx context.Context, known *cluster.Cluster) (*cluster.Cluster, Resource, error)
+	Expected(ctx context.Context, known *cluster.Cluster) (*cluster.Cluster, Resource, error)
+	Apply(ctx context.Context, actual, expected Resource, known *cluster.Cluster) (*cluster.Cluster, Resource, error)
+	Delete(ctx context.Context, actual Resource, known *cluster.Cluster) (*cluster.Cluster, Resource, error)
+}
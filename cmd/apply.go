@@ -0,0 +1,69 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/kubicorn/kubicorn/cloud"
+	"github.com/kubicorn/kubicorn/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// NewApplyCmd builds the `kubicorn apply` command. --timeout (registered on
+// the root command in root.go) bounds the whole run: once it elapses, ctx is
+// cancelled and every cloud.Resource call below returns instead of riding
+// out its retry/poll loop to completion.
+func NewApplyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply <cluster>",
+		Short: "Apply a cluster's expected state",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			known, resources, err := state.GetCluster(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := ContextWithTimeout(context.Background())
+			defer cancel()
+
+			_, err = ApplyResources(ctx, resources, known)
+			return err
+		},
+	}
+}
+
+// ApplyResources runs the Actual/Expected/Apply diff-and-reconcile cycle for
+// each resource against known, in order, honoring ctx cancellation at every
+// step instead of always running each retry/poll loop to completion.
+func ApplyResources(ctx context.Context, resources []cloud.Resource, known *cluster.Cluster) (*cluster.Cluster, error) {
+	for _, resource := range resources {
+		_, actual, err := resource.Actual(ctx, known)
+		if err != nil {
+			return nil, err
+		}
+		_, expected, err := resource.Expected(ctx, known)
+		if err != nil {
+			return nil, err
+		}
+		known, _, err = resource.Apply(ctx, actual, expected, known)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
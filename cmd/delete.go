@@ -0,0 +1,64 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/kubicorn/kubicorn/cloud"
+	"github.com/kubicorn/kubicorn/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// NewDeleteCmd builds the `kubicorn delete` command. --timeout (registered
+// on the root command in root.go) bounds the whole run the same way it does
+// for apply.
+func NewDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <cluster>",
+		Short: "Delete a cluster's resources",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			known, resources, err := state.GetCluster(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := ContextWithTimeout(context.Background())
+			defer cancel()
+
+			_, err = DeleteResources(ctx, resources, known)
+			return err
+		},
+	}
+}
+
+// DeleteResources runs Actual then Delete for each resource against known,
+// in order, honoring ctx cancellation at every step instead of always
+// running each retry/poll loop to completion.
+func DeleteResources(ctx context.Context, resources []cloud.Resource, known *cluster.Cluster) (*cluster.Cluster, error) {
+	for _, resource := range resources {
+		_, actual, err := resource.Actual(ctx, known)
+		if err != nil {
+			return nil, err
+		}
+		known, _, err = resource.Delete(ctx, actual, known)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
@@ -0,0 +1,32 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewRootCmd builds kubicorn's root command and registers the flags shared
+// by every subcommand, including --timeout.
+func NewRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "kubicorn",
+		Short: "kubicorn manages cloud infrastructure for Kubernetes clusters",
+	}
+	root.PersistentFlags().DurationVar(&Timeout, "timeout", 0,
+		"cancel the command after this long (0 disables the deadline)")
+	root.AddCommand(NewApplyCmd(), NewDeleteCmd())
+	return root
+}
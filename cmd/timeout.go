@@ -0,0 +1,38 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"time"
+)
+
+// Timeout bounds how long a single cluster operation (apply, delete, ...) is
+// allowed to run before its context is cancelled. Zero means no deadline,
+// matching kubicorn's historical behavior of running until the operation
+// itself returns. Commands register it with:
+//
+//	cmd.PersistentFlags().DurationVar(&cmd.Timeout, "timeout", 0, "...")
+var Timeout time.Duration
+
+// ContextWithTimeout returns a context derived from parent that is cancelled
+// after Timeout, or parent unchanged (with a no-op cancel) if Timeout is
+// zero.
+func ContextWithTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	if Timeout <= 0 {
+		return parent, func() {}
+	}
+	return context.WithTimeout(parent, Timeout)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package comms abstracts the remote shell kubicorn uses to reach a freshly
+// booted node during Apply, so provisioning isn't hard-wired to SSH. Droplet
+// bootstrap for Linux droplets stays on SSHCommunicator; Windows droplets
+// (and, eventually, Windows AMIs on AWS) use WinRMCommunicator instead.
+package comms
+
+import (
+	"fmt"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+)
+
+// Communicator reaches a single remote host to read files left behind by
+// cloud-init/bootstrap scripts. Provisioners and the VPN side-channel in
+// Droplet.Apply must talk to this interface, never to a concrete client.
+type Communicator interface {
+	Connect() error
+	Close() error
+	ReadFile(remotePath string) ([]byte, error)
+}
+
+// NewCommunicator builds the Communicator configured on serverPool, bound to
+// host. providerConfig supplies the legacy SSH defaults (port/user/key path)
+// for pools that haven't opted into the newer ServerPool communicator fields.
+func NewCommunicator(serverPool *cluster.ServerPool, providerConfig cluster.ProviderConfig, host string) (Communicator, error) {
+	switch serverPool.CommunicatorType {
+	case cluster.CommunicatorTypeWinRM:
+		return NewWinRMCommunicator(host, serverPool), nil
+	case cluster.CommunicatorTypeSSH, "":
+		return NewSSHCommunicator(host, serverPool, providerConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown communicator type [%s]", serverPool.CommunicatorType)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comms
+
+import (
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/kubicorn/kubicorn/pkg/scp"
+	"github.com/kubicorn/kubicorn/pkg/ssh"
+)
+
+var _ Communicator = &SSHCommunicator{}
+
+// SSHCommunicator is the default Communicator, unchanged from kubicorn's
+// existing SSH-based bootstrap reads.
+type SSHCommunicator struct {
+	client *ssh.SSHClient
+}
+
+// NewSSHCommunicator builds an SSHCommunicator, preferring the ServerPool's
+// communicator fields and falling back to the cluster-wide SSH settings used
+// before per-pool communicators existed.
+func NewSSHCommunicator(host string, serverPool *cluster.ServerPool, providerConfig cluster.ProviderConfig) *SSHCommunicator {
+	port := serverPool.CommunicatorPort
+	if port == "" {
+		port = providerConfig.SSH.Port
+	}
+	user := serverPool.CommunicatorUser
+	if user == "" {
+		user = providerConfig.SSH.User
+	}
+	cert := serverPool.CommunicatorCert
+	if cert == "" {
+		cert = providerConfig.SSH.PublicKeyPath
+	}
+	return &SSHCommunicator{
+		client: ssh.NewSSHClient(host, port, user, cert),
+	}
+}
+
+func (c *SSHCommunicator) Connect() error {
+	return c.client.Connect()
+}
+
+func (c *SSHCommunicator) Close() error {
+	return c.client.Close()
+}
+
+func (c *SSHCommunicator) ReadFile(remotePath string) ([]byte, error) {
+	return scp.ReadBytes(c.client, remotePath)
+}
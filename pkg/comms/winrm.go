@@ -0,0 +1,82 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package comms
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"github.com/masterzen/winrm"
+)
+
+var _ Communicator = &WinRMCommunicator{}
+
+const defaultWinRMPort = 5985
+
+// WinRMCommunicator reaches Windows droplets over WinRM, the peer kubicorn
+// needs alongside SSH to bootstrap Windows-based worker nodes.
+type WinRMCommunicator struct {
+	host     string
+	port     int
+	user     string
+	password string
+	client   *winrm.Client
+}
+
+// NewWinRMCommunicator builds a WinRMCommunicator from the ServerPool's
+// communicator settings.
+func NewWinRMCommunicator(host string, serverPool *cluster.ServerPool) *WinRMCommunicator {
+	port := defaultWinRMPort
+	if serverPool.CommunicatorPort != "" {
+		if p, err := strconv.Atoi(serverPool.CommunicatorPort); err == nil {
+			port = p
+		}
+	}
+	return &WinRMCommunicator{
+		host:     host,
+		port:     port,
+		user:     serverPool.CommunicatorUser,
+		password: serverPool.CommunicatorPassword,
+	}
+}
+
+func (c *WinRMCommunicator) Connect() error {
+	endpoint := winrm.NewEndpoint(c.host, c.port, false, false, nil, nil, nil, 0)
+	client, err := winrm.NewClient(endpoint, c.user, c.password)
+	if err != nil {
+		return fmt.Errorf("unable to create WinRM client: %v", err)
+	}
+	c.client = client
+	return nil
+}
+
+func (c *WinRMCommunicator) Close() error {
+	return nil
+}
+
+func (c *WinRMCommunicator) ReadFile(remotePath string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	command := fmt.Sprintf("powershell -Command \"Get-Content -Raw -Path '%s'\"", remotePath)
+	exitCode, err := c.client.Run(command, &stdout, &stderr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read [%s] over WinRM: %v", remotePath, err)
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("reading [%s] over WinRM exited [%d]: %s", remotePath, exitCode, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+	"gopkg.in/yaml.v2"
+)
+
+var _ Renderer = &CloudConfigRenderer{}
+
+const (
+	cloudConfigHeader      = "#cloud-config\n"
+	bootstrapScriptPath    = "/etc/kubicorn/bootstrap.sh"
+	vpnClientConfPath      = "/etc/openvpn/client.conf"
+	cloudConfigPermissions = "0755"
+)
+
+// CloudConfigRenderer emits cloud-config YAML instead of raw shell, so
+// bootstrap state (the VPN client config in particular) can travel as a
+// proper write_files entry rather than an escaped string.
+type CloudConfigRenderer struct{}
+
+type cloudConfigFile struct {
+	Path        string `yaml:"path"`
+	Content     string `yaml:"content"`
+	Permissions string `yaml:"permissions,omitempty"`
+}
+
+type cloudConfigUser struct {
+	Name              string   `yaml:"name"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+type cloudConfig struct {
+	WriteFiles        []cloudConfigFile `yaml:"write_files,omitempty"`
+	RunCmd            []string          `yaml:"runcmd,omitempty"`
+	SSHAuthorizedKeys []string          `yaml:"ssh_authorized_keys,omitempty"`
+	Users             []cloudConfigUser `yaml:"users,omitempty"`
+}
+
+func (c *CloudConfigRenderer) Render(bootstrapScripts []string, immutable *cluster.Cluster, values RenderValues) ([]byte, error) {
+	shell := &ShellScriptRenderer{}
+	rendered, err := shell.Render(bootstrapScripts, immutable, values)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &cloudConfig{
+		WriteFiles: []cloudConfigFile{
+			{
+				Path:        bootstrapScriptPath,
+				Content:     string(rendered),
+				Permissions: cloudConfigPermissions,
+			},
+		},
+		RunCmd: []string{fmt.Sprintf("bash %s", bootstrapScriptPath)},
+	}
+
+	if values.VPNConfig != "" {
+		config.WriteFiles = append(config.WriteFiles, cloudConfigFile{
+			Path:    vpnClientConfPath,
+			Content: values.VPNConfig,
+		})
+	}
+
+	body, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal cloud-config: %v", err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(cloudConfigHeader)
+	out.Write(body)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,116 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+)
+
+var _ Renderer = &IgnitionRenderer{}
+
+const ignitionVersion = "2.2.0"
+
+// IgnitionRenderer emits Ignition config JSON for Flatcar/CoreOS droplets.
+type IgnitionRenderer struct{}
+
+type ignitionConfig struct {
+	Ignition ignitionMeta    `json:"ignition"`
+	Storage  ignitionFiles   `json:"storage"`
+	Systemd  ignitionSystemd `json:"systemd,omitempty"`
+}
+
+type ignitionMeta struct {
+	Version string `json:"version"`
+}
+
+type ignitionFiles struct {
+	Files []ignitionFile `json:"files,omitempty"`
+}
+
+type ignitionFile struct {
+	Filesystem string              `json:"filesystem"`
+	Path       string              `json:"path"`
+	Mode       int                 `json:"mode"`
+	Contents   ignitionFileContent `json:"contents"`
+}
+
+type ignitionFileContent struct {
+	Source string `json:"source"`
+}
+
+type ignitionSystemd struct {
+	Units []ignitionUnit `json:"units,omitempty"`
+}
+
+type ignitionUnit struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Contents string `json:"contents"`
+}
+
+func (i *IgnitionRenderer) Render(bootstrapScripts []string, immutable *cluster.Cluster, values RenderValues) ([]byte, error) {
+	shell := &ShellScriptRenderer{}
+	rendered, err := shell.Render(bootstrapScripts, immutable, values)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ignitionConfig{
+		Ignition: ignitionMeta{Version: ignitionVersion},
+		Storage: ignitionFiles{
+			Files: []ignitionFile{
+				{
+					Filesystem: "root",
+					Path:       bootstrapScriptPath,
+					Mode:       0755,
+					Contents:   ignitionFileContent{Source: dataURL(string(rendered))},
+				},
+			},
+		},
+		Systemd: ignitionSystemd{
+			Units: []ignitionUnit{
+				{
+					Name:    "kubicorn-bootstrap.service",
+					Enabled: true,
+					Contents: fmt.Sprintf("[Service]\nType=oneshot\nExecStart=/bin/bash %s\n\n[Install]\nWantedBy=multi-user.target\n",
+						bootstrapScriptPath),
+				},
+			},
+		},
+	}
+
+	if values.VPNConfig != "" {
+		config.Storage.Files = append(config.Storage.Files, ignitionFile{
+			Filesystem: "root",
+			Path:       vpnClientConfPath,
+			Mode:       0644,
+			Contents:   ignitionFileContent{Source: dataURL(values.VPNConfig)},
+		})
+	}
+
+	body, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal Ignition config: %v", err)
+	}
+	return body, nil
+}
+
+func dataURL(content string) string {
+	return "data:," + url.PathEscape(content)
+}
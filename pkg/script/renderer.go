@@ -0,0 +1,52 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package script turns a ServerPool's bootstrap scripts into the UserData a
+// provider hands the instance on boot. The format is pluggable: plain shell
+// (the historical behavior), cloud-config YAML, or Ignition JSON, chosen by
+// ServerPool.UserDataFormat.
+package script
+
+import (
+	"github.com/kubicorn/kubicorn/apis/cluster"
+)
+
+// RenderValues carries the structured values a Renderer needs beyond the raw
+// bootstrap script bodies, so providers stop smuggling them in as escaped
+// strings inside ItemMap.
+type RenderValues struct {
+	MasterEndpoint string
+	Port           string
+	VPNConfig      string
+	ItemMap        map[string]string
+}
+
+// Renderer turns a set of bootstrap scripts plus RenderValues into the final
+// UserData payload for a given cloud-init format.
+type Renderer interface {
+	Render(bootstrapScripts []string, immutable *cluster.Cluster, values RenderValues) ([]byte, error)
+}
+
+// NewRenderer picks the Renderer for a ServerPool's UserDataFormat, defaulting
+// to the historical shell-script behavior when unset.
+func NewRenderer(format cluster.UserDataFormat) Renderer {
+	switch format {
+	case cluster.UserDataFormatCloudConfig:
+		return &CloudConfigRenderer{}
+	case cluster.UserDataFormatIgnition:
+		return &IgnitionRenderer{}
+	default:
+		return &ShellScriptRenderer{}
+	}
+}
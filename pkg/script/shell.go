@@ -0,0 +1,45 @@
+// Copyright © 2017 The Kubicorn Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/kubicorn/kubicorn/apis/cluster"
+)
+
+var _ Renderer = &ShellScriptRenderer{}
+
+// ShellScriptRenderer preserves kubicorn's original behavior: each bootstrap
+// script is templated against the cluster and concatenated into one shell
+// UserData payload.
+type ShellScriptRenderer struct{}
+
+func (s *ShellScriptRenderer) Render(bootstrapScripts []string, immutable *cluster.Cluster, values RenderValues) ([]byte, error) {
+	var out bytes.Buffer
+	for _, script := range bootstrapScripts {
+		tmpl, err := template.New(script).Parse(script)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse bootstrap script: %v", err)
+		}
+		if err := tmpl.Execute(&out, immutable); err != nil {
+			return nil, fmt.Errorf("unable to render bootstrap script: %v", err)
+		}
+		out.WriteString("\n")
+	}
+	return out.Bytes(), nil
+}